@@ -0,0 +1,176 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"sort"
+	"sync"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/cluster/router"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+)
+
+// RouterRuleConfig names one router a RouterChain should build, via the
+// PriorityRouterFactoryCreator registered under Name (e.g. by tag.init or condition.init),
+// plus that router's initial priority/source/rule.
+type RouterRuleConfig struct {
+	Name     string
+	Priority int64
+	Source   string
+	RuleYAML []byte
+}
+
+// cacheSetter is implemented by routers, such as TagRouter and ConditionRouter, that
+// accept the Cache their Poolable data was pooled into. It's kept separate from
+// router.PriorityRouter since not every router pools addresses.
+type cacheSetter interface {
+	SetCache(router.Cache)
+}
+
+// addrCache is the router.Cache RouterChain builds on every re-pool: a snapshot of the
+// invokers the chain last saw, plus whichever Poolable router built an AddrPool/
+// AddrMetadata against that exact snapshot.
+type addrCache struct {
+	invokers []base.Invoker
+	pools    map[router.Poolable]router.AddrPool
+	metas    map[router.Poolable]router.AddrMetadata
+}
+
+func (c *addrCache) GetInvokers() []base.Invoker {
+	return c.invokers
+}
+
+func (c *addrCache) FindAddrPool(p router.Poolable) router.AddrPool {
+	return c.pools[p]
+}
+
+func (c *addrCache) FindAddrMeta(p router.Poolable) router.AddrMetadata {
+	return c.metas[p]
+}
+
+// RouterChain is the actual consumer of router.GetPriorityRouterFactoryCreator: it builds
+// one PriorityRouter per RouterRuleConfig, keeps them sorted by ascending Priority, and
+// drives Pool/SetCache for the Poolable ones whenever Notify sees an invoker snapshot that
+// needs re-pooling, so Route itself never has to.
+type RouterChain struct {
+	url *common.URL
+
+	mu      sync.RWMutex
+	routers []router.PriorityRouter
+	cache   *addrCache
+}
+
+// NewRouterChain instantiates one PriorityRouter per cfg, via the creator registered under
+// cfg.Name, and returns them sorted by ascending Priority. It returns an error naming the
+// first cfg whose creator isn't registered, which usually means the package that registers
+// it (e.g. dubbo-go/cluster/router/tag) was never imported.
+func NewRouterChain(url *common.URL, cfgs []RouterRuleConfig) (*RouterChain, error) {
+	chain := &RouterChain{url: url}
+	for _, cfg := range cfgs {
+		creator := router.GetPriorityRouterFactoryCreator(cfg.Name)
+		if creator == nil {
+			return nil, perrors.Errorf("cluster/router/chain: no PriorityRouterFactoryCreator registered for %q; is its package imported?", cfg.Name)
+		}
+		factory, err := creator(url, cfg.Priority, cfg.Source, cfg.RuleYAML)
+		if err != nil {
+			return nil, perrors.WithStack(err)
+		}
+		r, err := factory.NewPriorityRouter()
+		if err != nil {
+			return nil, perrors.WithStack(err)
+		}
+		chain.routers = append(chain.routers, r)
+	}
+	sort.SliceStable(chain.routers, func(i, j int) bool {
+		return chain.routers[i].Priority() < chain.routers[j].Priority()
+	})
+	return chain, nil
+}
+
+// Notify hands the latest invoker snapshot to every router in the chain, then re-pools
+// whichever Poolable routers report ShouldPool against it — an address-list change or an
+// updated rule are the only reasons that's ever true — and publishes the result through
+// SetCache. A snapshot that needs no re-pooling just replaces the cached invoker list in
+// place, so the existing AddrPool/AddrMetadata stay valid without being rebuilt.
+func (c *RouterChain) Notify(invokers []base.Invoker) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, r := range c.routers {
+		r.Notify(invokers)
+	}
+
+	needsPool := false
+	for _, r := range c.routers {
+		if p, ok := r.(router.Poolable); ok && p.ShouldPool() {
+			needsPool = true
+			break
+		}
+	}
+	if !needsPool && c.cache != nil {
+		c.cache.invokers = invokers
+		return
+	}
+
+	cache := &addrCache{
+		invokers: invokers,
+		pools:    make(map[router.Poolable]router.AddrPool, len(c.routers)),
+		metas:    make(map[router.Poolable]router.AddrMetadata, len(c.routers)),
+	}
+	for _, r := range c.routers {
+		p, ok := r.(router.Poolable)
+		if !ok {
+			continue
+		}
+		pool, meta := p.Pool(invokers)
+		cache.pools[p] = pool
+		cache.metas[p] = meta
+	}
+	c.cache = cache
+
+	for _, r := range c.routers {
+		if setter, ok := r.(cacheSetter); ok {
+			setter.SetCache(cache)
+		}
+	}
+}
+
+// Route threads invokers through every router in the chain, in ascending Priority order,
+// each router narrowing (or passing through) the result the previous one produced.
+func (c *RouterChain) Route(invokers []base.Invoker, url *common.URL, invocation base.Invocation) []base.Invoker {
+	c.mu.RLock()
+	routers := c.routers
+	c.mu.RUnlock()
+
+	for _, r := range routers {
+		invokers = r.Route(invokers, url, invocation)
+	}
+	return invokers
+}
+
+// URL returns the URL the chain was built for.
+func (c *RouterChain) URL() *common.URL {
+	return c.url
+}
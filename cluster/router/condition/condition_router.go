@@ -0,0 +1,355 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package condition
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+)
+
+import (
+	"github.com/RoaringBitmap/roaring"
+
+	"gopkg.in/yaml.v2"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/cluster/router"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+)
+
+func init() {
+	router.SetPriorityRouterFactoryCreator(name, func(url *common.URL, priority int64, source string, ruleYAML []byte) (router.PriorityRouterFactory, error) {
+		return NewFactory(url, priority, source, ruleYAML), nil
+	})
+}
+
+// methodKey is the pseudo parameter name a ConditionRule's "when" clause uses to match
+// against the invocation's method name, since the method isn't a regular URL parameter.
+const methodKey = "method"
+
+// name is the Poolable name ConditionRouter registers its AddrPool/AddrMetadata under.
+const name = "condition-router"
+
+// ConditionRule is a single "when ... => then ..." clause: when every param in When
+// matches the invocation (its method name under methodKey, otherwise its attachments),
+// only invokers whose URL matches every param in Then are eligible. The consumer URL is
+// not consulted for When. A "*" value in either side matches anything.
+type ConditionRule struct {
+	When map[string]string `yaml:"when"`
+	Then map[string]string `yaml:"then"`
+}
+
+// ConditionRoutingRule is the YAML-driven rule set a ConditionRouter matches against.
+type ConditionRoutingRule struct {
+	Conditions []ConditionRule `yaml:"conditions"`
+	Force      bool            `yaml:"force"`
+}
+
+// conditionAddrMetadata records where a ConditionRouter's pooled rule came from, e.g. a
+// config center path, so operators can trace a routing decision back to its rule. It also
+// carries the router.SortedInvokers order Pool assigned bitmap positions over, so Route's
+// cache-hit branch can resolve a bitmap back to invokers without re-sorting on every call.
+type conditionAddrMetadata struct {
+	source string
+	sorted []base.Invoker
+}
+
+// Source indicates where the metadata comes from.
+func (m *conditionAddrMetadata) Source() string {
+	return m.source
+}
+
+// ConditionRouter matches invocations against a small set of when/then rules and pools
+// one bitmap per matched condition key, so Route avoids an O(N) scan of invokers against
+// every rule's Then clause on every call.
+type ConditionRouter struct {
+	url      *common.URL
+	priority int64
+	source   string
+
+	cache router.Cache
+
+	mu                sync.RWMutex
+	rule              *ConditionRoutingRule
+	ruleHash          string
+	invokerHash       string
+	pooledRuleHash    string
+	pooledInvokerHash string
+}
+
+// NewConditionRouter builds a ConditionRouter from YAML rule bytes, as loaded from a
+// config center path or a local rule file.
+func NewConditionRouter(url *common.URL, priority int64, source string, ruleYAML []byte) (*ConditionRouter, error) {
+	r := &ConditionRouter{
+		url:      url,
+		priority: priority,
+		source:   source,
+	}
+	if len(ruleYAML) > 0 {
+		if err := r.UpdateRule(ruleYAML); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// UpdateRule parses and swaps in a new YAML rule, e.g. after a config center push.
+func (r *ConditionRouter) UpdateRule(ruleYAML []byte) error {
+	rule := &ConditionRoutingRule{}
+	if err := yaml.Unmarshal(ruleYAML, rule); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(ruleYAML)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rule = rule
+	r.ruleHash = hex.EncodeToString(sum[:])
+	return nil
+}
+
+// SetCache wires in the Cache the router chain maintains for this invoker snapshot, so
+// Route can look up the bitmaps Pool built without needing a Cache passed into Route
+// itself. The chain calls this once per snapshot, before invoking Route.
+func (r *ConditionRouter) SetCache(cache router.Cache) {
+	r.mu.Lock()
+	r.cache = cache
+	r.mu.Unlock()
+}
+
+// Route finds the first rule whose When clause matches invocation, then returns only the
+// invokers pooled for that rule's Then clause, ANDed against both the healthy-invoker
+// bitmap Pool captured and the full invoker set passed in. Falls back to a plain scan when
+// the router hasn't been pooled yet.
+func (r *ConditionRouter) Route(invokers []base.Invoker, url *common.URL, invocation base.Invocation) []base.Invoker {
+	r.mu.RLock()
+	rule := r.rule
+	cache := r.cache
+	r.mu.RUnlock()
+
+	if rule == nil {
+		return invokers
+	}
+
+	for _, cond := range rule.Conditions {
+		if !matchInvocation(cond.When, invocation) {
+			continue
+		}
+
+		if cache != nil {
+			if pool := cache.FindAddrPool(r); pool != nil {
+				if bm, ok := pool[conditionKey(cond)]; ok {
+					if meta, ok := cache.FindAddrMeta(r).(*conditionAddrMetadata); ok {
+						result, fallback := router.ResolveBitmap(bm, pool[healthyPoolKey], rule.Force)
+						if fallback {
+							return invokers
+						}
+						present := make(map[string]base.Invoker, len(invokers))
+						for _, invoker := range invokers {
+							present[invoker.GetURL().Key()] = invoker
+						}
+						matched := make([]base.Invoker, 0, result.GetCardinality())
+						for _, invoker := range router.PickInvokers(meta.sorted, result) {
+							if passed, ok := present[invoker.GetURL().Key()]; ok {
+								matched = append(matched, passed)
+							}
+						}
+						if len(matched) == 0 && !rule.Force {
+							return invokers
+						}
+						return matched
+					}
+				}
+			}
+		}
+
+		matched := make([]base.Invoker, 0, len(invokers))
+		for _, invoker := range invokers {
+			if matchParams(cond.Then, invoker.GetURL()) {
+				matched = append(matched, invoker)
+			}
+		}
+		if len(matched) == 0 && !rule.Force {
+			return invokers
+		}
+		return matched
+	}
+
+	return invokers
+}
+
+// matchInvocation reports whether every param in when matches invocation, treating
+// methodKey specially since the method name isn't carried as an attachment.
+func matchInvocation(when map[string]string, invocation base.Invocation) bool {
+	for k, v := range when {
+		if v == "*" {
+			continue
+		}
+		var actual string
+		if k == methodKey {
+			actual = invocation.MethodName()
+		} else {
+			actual = invocation.Attachment(k, "")
+		}
+		if actual != v {
+			return false
+		}
+	}
+	return true
+}
+
+// matchParams reports whether every param in then matches the invoker's URL.
+func matchParams(then map[string]string, url *common.URL) bool {
+	for k, v := range then {
+		if v == "*" {
+			continue
+		}
+		if url.GetParam(k, "") != v {
+			return false
+		}
+	}
+	return true
+}
+
+// conditionKey canonicalizes a rule's Then clause into a stable AddrPool map key.
+func conditionKey(cond ConditionRule) string {
+	keys := make([]string, 0, len(cond.Then))
+	for k := range cond.Then {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+cond.Then[k])
+	}
+	return strings.Join(parts, "&")
+}
+
+// URL Return URL in router
+func (r *ConditionRouter) URL() *common.URL {
+	return r.url
+}
+
+// Priority Return Priority in router
+func (r *ConditionRouter) Priority() int64 {
+	return r.priority
+}
+
+// Notify the router the invoker list. The invoker set hash is recomputed here so
+// ShouldPool can detect an address-list change without needing the invokers itself.
+func (r *ConditionRouter) Notify(invokers []base.Invoker) {
+	r.mu.Lock()
+	r.invokerHash = router.HashInvokers(invokers)
+	r.mu.Unlock()
+}
+
+// healthyPoolKey is the reserved AddrPool key Pool stores the all-healthy-invoker bitmap
+// under. It can't collide with a real conditionKey since those are built from "k=v&..."
+// pairs and never contain a NUL byte.
+const healthyPoolKey = "\x00healthy"
+
+// Pool scans invokers once and builds one *roaring.Bitmap per matched condition key,
+// recording the index of every invoker whose URL matches that condition's Then clause,
+// plus one bitmap under healthyPoolKey recording every invoker that's currently available.
+// Bitmap positions are assigned over router.SortedInvokers rather than invokers' own order, so
+// Route can recover the same positions later even if the invoker list arrives in a
+// different order by then.
+func (r *ConditionRouter) Pool(invokers []base.Invoker) (router.AddrPool, router.AddrMetadata) {
+	r.mu.RLock()
+	rule := r.rule
+	r.mu.RUnlock()
+
+	sorted := router.SortedInvokers(invokers)
+
+	healthy := roaring.New()
+	for idx, invoker := range sorted {
+		if invoker.IsAvailable() {
+			healthy.Add(uint32(idx))
+		}
+	}
+
+	pool := make(router.AddrPool)
+	pool[healthyPoolKey] = healthy
+	if rule != nil {
+		for _, cond := range rule.Conditions {
+			key := conditionKey(cond)
+			bm := roaring.New()
+			for idx, invoker := range sorted {
+				if matchParams(cond.Then, invoker.GetURL()) {
+					bm.Add(uint32(idx))
+				}
+			}
+			// An empty bitmap is not pooled: leaving the key absent makes Route miss the
+			// cache for this condition and fall through to its scan path, the same
+			// force-aware check a cold router takes.
+			if !bm.IsEmpty() {
+				pool[key] = bm
+			}
+		}
+	}
+
+	r.mu.Lock()
+	r.invokerHash = router.HashInvokers(invokers)
+	r.pooledRuleHash = r.ruleHash
+	r.pooledInvokerHash = r.invokerHash
+	r.mu.Unlock()
+
+	return pool, &conditionAddrMetadata{source: r.source, sorted: sorted}
+}
+
+// ShouldPool returns true whenever the rule text or the invoker set hashes change since
+// the last Pool call, which is the only time re-pooling is actually necessary.
+func (r *ConditionRouter) ShouldPool() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.ruleHash != r.pooledRuleHash || r.invokerHash != r.pooledInvokerHash
+}
+
+// Name return the Poolable's name.
+func (r *ConditionRouter) Name() string {
+	return name
+}
+
+// Factory implements router.PriorityRouterFactory, building a ConditionRouter bound to a
+// fixed URL/priority/source/rule. init() registers a creator for it under Name() via
+// router.SetPriorityRouterFactoryCreator, so a chain builder can instantiate one by name
+// without importing this package directly.
+type Factory struct {
+	url      *common.URL
+	priority int64
+	source   string
+	ruleYAML []byte
+}
+
+// NewFactory builds a Factory for the condition router identified by source (e.g. a
+// config center rule path), with ruleYAML as its initial rule.
+func NewFactory(url *common.URL, priority int64, source string, ruleYAML []byte) *Factory {
+	return &Factory{url: url, priority: priority, source: source, ruleYAML: ruleYAML}
+}
+
+// NewPriorityRouter creates router instance with URL
+func (f *Factory) NewPriorityRouter() (router.PriorityRouter, error) {
+	return NewConditionRouter(f.url, f.priority, f.source, f.ruleYAML)
+}
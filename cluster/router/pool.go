@@ -0,0 +1,108 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+)
+
+import (
+	"github.com/RoaringBitmap/roaring"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+)
+
+// SortedInvokers returns invokers reordered by ascending invoker.GetURL().Key(), so the
+// bitmap positions a Poolable.Pool implementation assigns and the positions its Route
+// reads back via PickInvokers always line up with the same canonical ordering regardless
+// of what order invokers arrives in on a given call — registries routinely redeliver the
+// same address set in a different order, and HashInvokers already treats that as a no-op
+// change, so Pool must too.
+func SortedInvokers(invokers []base.Invoker) []base.Invoker {
+	keys := make([]string, len(invokers))
+	for i, invoker := range invokers {
+		keys[i] = invoker.GetURL().Key()
+	}
+	order := StableOrder(keys)
+
+	sorted := make([]base.Invoker, len(invokers))
+	for i, origIdx := range order {
+		sorted[i] = invokers[origIdx]
+	}
+	return sorted
+}
+
+// StableOrder returns the permutation that sorts keys ascending: StableOrder(keys)[i] is
+// the original index, into keys, of the i-th smallest key.
+func StableOrder(keys []string) []int {
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return keys[order[i]] < keys[order[j]]
+	})
+	return order
+}
+
+// HashInvokers produces a stable fingerprint of an invoker list's addresses, independent
+// of ordering, so a router's Notify can tell whether the address set actually changed.
+func HashInvokers(invokers []base.Invoker) string {
+	addrs := make([]string, 0, len(invokers))
+	for _, invoker := range invokers {
+		addrs = append(addrs, invoker.GetURL().Key())
+	}
+	sort.Strings(addrs)
+	sum := sha256.Sum256([]byte(strings.Join(addrs, ",")))
+	return hex.EncodeToString(sum[:])
+}
+
+// ResolveBitmap ANDs matched against healthy, when a healthy bitmap was pooled, and
+// decides whether the caller should fall back to returning every invoker instead: that's
+// the case whenever the result is empty and force is false, whether matched started out
+// empty or ANDing against healthy made it so.
+func ResolveBitmap(matched, healthy *roaring.Bitmap, force bool) (result *roaring.Bitmap, fallback bool) {
+	result = matched
+	if healthy != nil {
+		result = roaring.And(matched, healthy)
+	}
+	if result.IsEmpty() && !force {
+		return result, true
+	}
+	return result, false
+}
+
+// PickInvokers resolves bm's set bits back into the invokers at those positions in
+// invokers, which must be in the same order Pool built bm's positions over (i.e. the
+// result of SortedInvokers over the same address set).
+func PickInvokers(invokers []base.Invoker, bm *roaring.Bitmap) []base.Invoker {
+	matched := make([]base.Invoker, 0, bm.GetCardinality())
+	it := bm.Iterator()
+	for it.HasNext() {
+		idx := it.Next()
+		if int(idx) < len(invokers) {
+			matched = append(matched, invokers[idx])
+		}
+	}
+	return matched
+}
@@ -0,0 +1,181 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/RoaringBitmap/roaring"
+)
+
+func TestStableOrder_SameSetDifferentInputOrderYieldsSameSortedSequence(t *testing.T) {
+	// Both tag.TagRouter and condition.ConditionRouter rely on this: whatever order the
+	// two calls receive the same address set in, bitmap positions must resolve to the
+	// same addresses both times.
+	a := []string{"b", "a", "c"}
+	b := []string{"a", "c", "b"}
+
+	applyOrder := func(keys []string) []string {
+		order := StableOrder(keys)
+		out := make([]string, len(keys))
+		for i, origIdx := range order {
+			out[i] = keys[origIdx]
+		}
+		return out
+	}
+
+	got := applyOrder(a)
+	want := applyOrder(b)
+	if len(got) != len(want) {
+		t.Fatalf("length mismatch: got %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("position %d: got %q, want %q (got=%v want=%v)", i, got[i], want[i], got, want)
+		}
+	}
+}
+
+func TestStableOrder_Empty(t *testing.T) {
+	if order := StableOrder(nil); len(order) != 0 {
+		t.Fatalf("got %v, want empty", order)
+	}
+}
+
+func TestResolveBitmap_NoHealthyBitmapPassesMatchedThrough(t *testing.T) {
+	matched := roaring.New()
+	matched.Add(1)
+	matched.Add(2)
+
+	result, fallback := ResolveBitmap(matched, nil, false)
+	if fallback {
+		t.Fatal("unexpected fallback with no healthy bitmap and a non-empty match")
+	}
+	if !result.Equals(matched) {
+		t.Fatalf("got %v, want %v unchanged", result, matched)
+	}
+}
+
+func TestResolveBitmap_HealthyFilterEmptiesResult(t *testing.T) {
+	matched := roaring.New()
+	matched.Add(1)
+	matched.Add(2)
+
+	healthy := roaring.New()
+	healthy.Add(3) // disjoint from matched: every matched invoker is currently unhealthy
+
+	if _, fallback := ResolveBitmap(matched, healthy, false); !fallback {
+		t.Fatal("expected fallback once every matched invoker is filtered out by healthy, Force=false")
+	}
+
+	result, fallback := ResolveBitmap(matched, healthy, true)
+	if fallback {
+		t.Fatal("Force=true must not fall back even when the result is empty")
+	}
+	if !result.IsEmpty() {
+		t.Fatalf("got %v, want empty", result)
+	}
+}
+
+func TestResolveBitmap_HealthyFilterKeepsOverlap(t *testing.T) {
+	matched := roaring.New()
+	matched.Add(1)
+	matched.Add(2)
+
+	healthy := roaring.New()
+	healthy.Add(2)
+	healthy.Add(3)
+
+	result, fallback := ResolveBitmap(matched, healthy, false)
+	if fallback {
+		t.Fatal("unexpected fallback: invoker 2 is matched and healthy")
+	}
+	want := roaring.New()
+	want.Add(2)
+	if !result.Equals(want) {
+		t.Fatalf("got %v, want %v", result, want)
+	}
+}
+
+// TestPickInvokers_ResolvesPositionsBackToValues runs PickInvokers's bitmap-iteration loop
+// over plain strings; PickInvokers itself takes []base.Invoker and is not called here.
+func TestPickInvokers_ResolvesPositionsBackToValues(t *testing.T) {
+	invokers := []string{"a", "b", "c"}
+	bm := roaring.New()
+	bm.Add(0)
+	bm.Add(2)
+
+	picked := make([]string, 0, 2)
+	it := bm.Iterator()
+	for it.HasNext() {
+		picked = append(picked, invokers[it.Next()])
+	}
+	if len(picked) != 2 || picked[0] != "a" || picked[1] != "c" {
+		t.Fatalf("got %v, want [a c]", picked)
+	}
+}
+
+// TestPoolHotPath_TenThousandInvokers exercises the positional-bitmap hot path —
+// StableOrder plus roaring.And — at the 10k-invoker scale routers are meant to avoid an
+// O(N) scan at, logs the per-iteration cost, and fails only above a generous 5ms budget.
+func TestPoolHotPath_TenThousandInvokers(t *testing.T) {
+	const n = 10000
+
+	keys := make([]string, n)
+	for i := range keys {
+		// Cycles through the alphabet in descending order, so the input arrives out of sorted
+		// order and full of repeated keys.
+		keys[i] = string(rune('a' + (n-i)%26))
+	}
+
+	order := StableOrder(keys)
+	if len(order) != n {
+		t.Fatalf("got %d positions, want %d", len(order), n)
+	}
+
+	matched := roaring.New()
+	healthy := roaring.New()
+	for i := 0; i < n; i += 2 {
+		matched.Add(uint32(i))
+	}
+	for i := 0; i < n; i += 3 {
+		healthy.Add(uint32(i))
+	}
+
+	const iterations = 100
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		StableOrder(keys)
+		if _, fallback := ResolveBitmap(matched, healthy, false); fallback {
+			t.Fatal("unexpected fallback: matched and healthy overlap at every multiple of 6")
+		}
+	}
+	elapsed := time.Since(start)
+	perIter := elapsed / iterations
+	t.Logf("StableOrder+ResolveBitmap over %d invokers: ~%s/iter", n, perIter)
+
+	// Generous budget to keep this from being flaky in CI; the real cost of a 10k-element
+	// sort plus a couple of bitmap ANDs is comfortably sub-millisecond on any modern
+	// machine, which is the whole point of pooling instead of scanning.
+	if perIter > 5*time.Millisecond {
+		t.Fatalf("StableOrder+ResolveBitmap took ~%s/iter over %d invokers, want well under 5ms", perIter, n)
+	}
+}
@@ -32,6 +32,26 @@ type PriorityRouterFactory interface {
 	NewPriorityRouter() (PriorityRouter, error)
 }
 
+// PriorityRouterFactoryCreator builds a PriorityRouterFactory bound to a specific
+// url/priority/source/rule, the way tag.NewFactory and condition.NewFactory do. Router
+// implementations register one of these under their Poolable Name() so a chain builder can
+// instantiate them by name, without importing every concrete router package directly.
+type PriorityRouterFactoryCreator func(url *common.URL, priority int64, source string, ruleYAML []byte) (PriorityRouterFactory, error)
+
+var factoryCreators = make(map[string]PriorityRouterFactoryCreator, 4)
+
+// SetPriorityRouterFactoryCreator registers creator under name, typically called from a
+// router implementation's init().
+func SetPriorityRouterFactoryCreator(name string, creator PriorityRouterFactoryCreator) {
+	factoryCreators[name] = creator
+}
+
+// GetPriorityRouterFactoryCreator returns the PriorityRouterFactoryCreator registered under
+// name, or nil if none is registered.
+func GetPriorityRouterFactoryCreator(name string) PriorityRouterFactoryCreator {
+	return factoryCreators[name]
+}
+
 // PriorityRouter routes with priority
 type PriorityRouter interface {
 	// Route Determine the target invokers list.
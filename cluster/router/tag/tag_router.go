@@ -0,0 +1,293 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+import (
+	"github.com/RoaringBitmap/roaring"
+
+	"gopkg.in/yaml.v2"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/cluster/router"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+)
+
+func init() {
+	router.SetPriorityRouterFactoryCreator(name, func(url *common.URL, priority int64, source string, ruleYAML []byte) (router.PriorityRouterFactory, error) {
+		return NewFactory(url, priority, source, ruleYAML), nil
+	})
+}
+
+// TagKey is the invocation attachment carrying the tag a request should be routed to.
+const TagKey = "dubbo.tag"
+
+// TagInvokerKey is the invoker URL parameter declaring which tag an invoker belongs to.
+const TagInvokerKey = "tag"
+
+// name is the Poolable name TagRouter registers its AddrPool/AddrMetadata under.
+const name = "tag-router"
+
+// TagRoutingRule is the YAML-driven rule a TagRouter is configured with: a flat
+// tag -> addresses mapping plus whether requests without a matching tagged invoker should
+// still be allowed to fall back to every invoker. Only Force is consulted by Route and
+// Pool; Tags is parsed but not read, since an invoker's tag comes from its TagInvokerKey
+// URL parameter.
+type TagRoutingRule struct {
+	Tags  map[string][]string `yaml:"tags"`
+	Force bool                `yaml:"force"`
+}
+
+// tagAddrMetadata records where a TagRouter's pooled rule came from, e.g. a config center
+// path, so operators can trace a routing decision back to the rule that produced it. It
+// also carries the router.SortedInvokers order Pool assigned bitmap positions over, so
+// Route's cache-hit branch can resolve a bitmap back to invokers without re-sorting on
+// every call.
+type tagAddrMetadata struct {
+	source string
+	sorted []base.Invoker
+}
+
+// Source indicates where the metadata comes from.
+func (m *tagAddrMetadata) Source() string {
+	return m.source
+}
+
+// TagRouter routes by the TagKey attachment on the invocation, using a pooled bitmap per
+// tag value so Route avoids an O(N) scan of invokers on every call.
+type TagRouter struct {
+	url      *common.URL
+	priority int64
+	source   string
+
+	cache router.Cache
+
+	mu                sync.RWMutex
+	rule              *TagRoutingRule
+	ruleHash          string
+	invokerHash       string
+	pooledRuleHash    string
+	pooledInvokerHash string
+}
+
+// SetCache wires in the Cache the router chain maintains for this invoker snapshot, so
+// Route can look up the bitmaps Pool built without needing a Cache passed into Route
+// itself. The chain calls this once per snapshot, before invoking Route.
+func (r *TagRouter) SetCache(cache router.Cache) {
+	r.mu.Lock()
+	r.cache = cache
+	r.mu.Unlock()
+}
+
+// NewTagRouter builds a TagRouter from YAML rule bytes, as loaded from a config center
+// path or a local rule file.
+func NewTagRouter(url *common.URL, priority int64, source string, ruleYAML []byte) (*TagRouter, error) {
+	r := &TagRouter{
+		url:      url,
+		priority: priority,
+		source:   source,
+	}
+	if len(ruleYAML) > 0 {
+		if err := r.UpdateRule(ruleYAML); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// UpdateRule parses and swaps in a new YAML rule, e.g. after a config center push.
+func (r *TagRouter) UpdateRule(ruleYAML []byte) error {
+	rule := &TagRoutingRule{}
+	if err := yaml.Unmarshal(ruleYAML, rule); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(ruleYAML)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rule = rule
+	r.ruleHash = hex.EncodeToString(sum[:])
+	return nil
+}
+
+// Route extracts the TagKey attachment from invocation and returns only the invokers
+// pooled under that tag, ANDed against both the healthy-invoker bitmap Pool captured and
+// the full set of invokers passed in. Falls back to every invoker passed in, tagged or not,
+// when the rule is configured to Force=false and no tagged, healthy invoker is available,
+// and to a plain scan when the router hasn't been pooled yet.
+func (r *TagRouter) Route(invokers []base.Invoker, url *common.URL, invocation base.Invocation) []base.Invoker {
+	tag := invocation.Attachment(TagKey, "")
+	if tag == "" {
+		return invokers
+	}
+
+	r.mu.RLock()
+	cache := r.cache
+	force := r.rule != nil && r.rule.Force
+	r.mu.RUnlock()
+
+	if cache != nil {
+		if pool := cache.FindAddrPool(r); pool != nil {
+			if bm, ok := pool[tag]; ok {
+				if meta, ok := cache.FindAddrMeta(r).(*tagAddrMetadata); ok {
+					result, fallback := router.ResolveBitmap(bm, pool[healthyPoolKey], force)
+					if fallback {
+						return invokers
+					}
+					present := make(map[string]base.Invoker, len(invokers))
+					for _, invoker := range invokers {
+						present[invoker.GetURL().Key()] = invoker
+					}
+					matched := make([]base.Invoker, 0, result.GetCardinality())
+					for _, invoker := range router.PickInvokers(meta.sorted, result) {
+						if passed, ok := present[invoker.GetURL().Key()]; ok {
+							matched = append(matched, passed)
+						}
+					}
+					if len(matched) == 0 && !force {
+						return invokers
+					}
+					return matched
+				}
+			}
+		}
+	}
+
+	return r.routeByScan(invokers, tag)
+}
+
+func (r *TagRouter) routeByScan(invokers []base.Invoker, tag string) []base.Invoker {
+	matched := make([]base.Invoker, 0, len(invokers))
+	for _, invoker := range invokers {
+		if invoker.GetURL().GetParam(TagInvokerKey, "") == tag {
+			matched = append(matched, invoker)
+		}
+	}
+
+	r.mu.RLock()
+	force := r.rule != nil && r.rule.Force
+	r.mu.RUnlock()
+
+	if len(matched) == 0 && !force {
+		return invokers
+	}
+	return matched
+}
+
+// URL Return URL in router
+func (r *TagRouter) URL() *common.URL {
+	return r.url
+}
+
+// Priority Return Priority in router
+func (r *TagRouter) Priority() int64 {
+	return r.priority
+}
+
+// Notify the router the invoker list. The invoker set hash is recomputed here so
+// ShouldPool can detect an address-list change without needing the invokers itself.
+func (r *TagRouter) Notify(invokers []base.Invoker) {
+	r.mu.Lock()
+	r.invokerHash = router.HashInvokers(invokers)
+	r.mu.Unlock()
+}
+
+// healthyPoolKey is the reserved AddrPool key Pool stores the all-healthy-invoker bitmap
+// under. It can't collide with a real tag value since TagInvokerKey values come off a URL
+// parameter and so never contain a NUL byte.
+const healthyPoolKey = "\x00healthy"
+
+// Pool scans invokers once and builds one *roaring.Bitmap per tag value, recording the
+// index of every invoker that carries that tag, plus one bitmap under healthyPoolKey
+// recording every invoker that's currently available. Bitmap positions are assigned over
+// router.SortedInvokers rather than invokers' own order, so Route can recover the same positions
+// later even if the invoker list arrives in a different order by then.
+func (r *TagRouter) Pool(invokers []base.Invoker) (router.AddrPool, router.AddrMetadata) {
+	sorted := router.SortedInvokers(invokers)
+
+	pool := make(router.AddrPool)
+	healthy := roaring.New()
+	for idx, invoker := range sorted {
+		if invoker.IsAvailable() {
+			healthy.Add(uint32(idx))
+		}
+
+		tag := invoker.GetURL().GetParam(TagInvokerKey, "")
+		if tag == "" {
+			continue
+		}
+		bm, ok := pool[tag]
+		if !ok {
+			bm = roaring.New()
+			pool[tag] = bm
+		}
+		bm.Add(uint32(idx))
+	}
+	pool[healthyPoolKey] = healthy
+
+	r.mu.Lock()
+	r.invokerHash = router.HashInvokers(invokers)
+	r.pooledRuleHash = r.ruleHash
+	r.pooledInvokerHash = r.invokerHash
+	r.mu.Unlock()
+
+	return pool, &tagAddrMetadata{source: r.source, sorted: sorted}
+}
+
+// ShouldPool returns true whenever the rule text or the invoker set hashes change since
+// the last Pool call, which is the only time re-pooling is actually necessary.
+func (r *TagRouter) ShouldPool() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.ruleHash != r.pooledRuleHash || r.invokerHash != r.pooledInvokerHash
+}
+
+// Name return the Poolable's name.
+func (r *TagRouter) Name() string {
+	return name
+}
+
+// Factory implements router.PriorityRouterFactory, building a TagRouter bound to a fixed
+// URL/priority/source/rule. init() registers a creator for it under Name() via
+// router.SetPriorityRouterFactoryCreator, so a chain builder can instantiate one by name
+// without importing this package directly.
+type Factory struct {
+	url      *common.URL
+	priority int64
+	source   string
+	ruleYAML []byte
+}
+
+// NewFactory builds a Factory for the tag router identified by source (e.g. a config
+// center rule path), with ruleYAML as its initial rule.
+func NewFactory(url *common.URL, priority int64, source string, ruleYAML []byte) *Factory {
+	return &Factory{url: url, priority: priority, source: source, ruleYAML: ruleYAML}
+}
+
+// NewPriorityRouter creates router instance with URL
+func (f *Factory) NewPriorityRouter() (router.PriorityRouter, error) {
+	return NewTagRouter(f.url, f.priority, f.source, f.ruleYAML)
+}
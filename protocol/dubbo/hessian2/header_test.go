@@ -0,0 +1,95 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hessian2
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestDubboHeader_IsHeartbeat_SurvivesEveryCombination(t *testing.T) {
+	// A heartbeat request packet is realistically PackageHeartbeat|PackageRequest|PackageRequest_TwoWay,
+	// not just PackageHeartbeat|PackageRequest. IsHeartbeat must say true regardless of
+	// which other bits ride along with it.
+	combos := []PackageType{
+		PackageHeartbeat | PackageRequest,
+		PackageHeartbeat | PackageRequest | PackageRequest_TwoWay,
+		PackageHeartbeat | PackageResponse,
+		PackageHeartbeat | PackageResponse | PackageResponse_Exception,
+	}
+	for _, pt := range combos {
+		h := DubboHeader{Type: pt}
+		if !h.IsHeartbeat() {
+			t.Errorf("IsHeartbeat() = false for Type %v, want true", pt)
+		}
+	}
+}
+
+func TestDubboHeader_IsRequestIsResponse_MutuallyExclusive(t *testing.T) {
+	req := DubboHeader{Type: PackageHeartbeat | PackageRequest | PackageRequest_TwoWay}
+	if !req.IsRequest() || req.IsResponse() {
+		t.Errorf("heartbeat request: IsRequest()=%v IsResponse()=%v, want true/false", req.IsRequest(), req.IsResponse())
+	}
+
+	resp := DubboHeader{Type: PackageHeartbeat | PackageResponse | PackageResponse_Exception}
+	if !resp.IsResponse() || resp.IsRequest() {
+		t.Errorf("heartbeat response: IsRequest()=%v IsResponse()=%v, want false/true", resp.IsRequest(), resp.IsResponse())
+	}
+}
+
+func TestDubboHeader_IsException(t *testing.T) {
+	ok := DubboHeader{Type: PackageResponse}
+	if ok.IsException() {
+		t.Error("plain response reported as exception")
+	}
+
+	exc := DubboHeader{Type: PackageResponse | PackageResponse_Exception}
+	if !exc.IsException() {
+		t.Error("exception response not reported as exception")
+	}
+}
+
+// TestHessianCodec_ReadAttachments_ExceptionResponseSkipsDecode exercises the
+// ReadAttachments dispatch switch directly, not just the IsXxx helpers: an exception
+// response's body is a plain hessian2-encoded string (see ReadBody), not the attachments
+// envelope any Serializer.UnmarshalResponseAttachments expects, so ReadAttachments must
+// recognize IsException before falling into the general IsResponse case and return (nil,
+// nil) without attempting to decode it as one.
+func TestHessianCodec_ReadAttachments_ExceptionResponseSkipsDecode(t *testing.T) {
+	// Deliberately not a valid attachments envelope for any registered serializer: if
+	// ReadAttachments ever mis-dispatches this to UnmarshalResponseAttachments, decoding
+	// garbage bytes as hessian2 would surface as an error instead of (nil, nil).
+	garbage := []byte{0xff, 0xff, 0xff, 0xff}
+
+	h := &HessianCodec{
+		pkgType:  PackageResponse | PackageResponse_Exception,
+		reader:   bufio.NewReader(bytes.NewReader(garbage)),
+		bodyLen:  len(garbage),
+		serialID: SerialID_Hessian2,
+		stream:   true,
+	}
+
+	attachments, err := h.ReadAttachments()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attachments != nil {
+		t.Fatalf("got %v, want nil attachments for an exception response", attachments)
+	}
+}
@@ -0,0 +1,198 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hessian2
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+import (
+	"github.com/apache/dubbo-go-hessian2"
+)
+
+func TestHessianCodec_ReadEvent_NonHeartbeatPacketErrors(t *testing.T) {
+	h := &HessianCodec{pkgType: PackageRequest | PackageRequest_TwoWay}
+	if _, err := h.ReadEvent(); err == nil {
+		t.Fatal("expected an error reading an event off a non-heartbeat packet")
+	}
+}
+
+func TestHessianCodec_ReadEvent_ZeroBodyLenIsAPlainHeartbeat(t *testing.T) {
+	h := &HessianCodec{pkgType: PackageHeartbeat | PackageRequest | PackageRequest_TwoWay, bodyLen: 0}
+	event, err := h.ReadEvent()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Event != nil || event.Readonly {
+		t.Fatalf("got %+v, want a zero-value DubboEvent for a null-body heartbeat", event)
+	}
+}
+
+func TestHessianCodec_ReadEvent_ReadonlyEvent(t *testing.T) {
+	encoder := hessian.NewEncoder()
+	if err := encoder.Encode(EventReadonly); err != nil {
+		t.Fatalf("failed to encode fixture: %v", err)
+	}
+	body := encoder.Buffer()
+
+	h := &HessianCodec{
+		pkgType: PackageHeartbeat | PackageResponse,
+		reader:  bufio.NewReader(bytes.NewReader(body)),
+		bodyLen: len(body),
+		stream:  true,
+	}
+	event, err := h.ReadEvent()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !event.Readonly {
+		t.Fatalf("got Readonly=false for event %v, want true", event.Event)
+	}
+	if s, ok := event.Event.(string); !ok || s != EventReadonly {
+		t.Fatalf("got Event=%v, want %q", event.Event, EventReadonly)
+	}
+}
+
+func TestHessianCodec_ReadEvent_NonReadonlyEventStaysFalse(t *testing.T) {
+	encoder := hessian.NewEncoder()
+	if err := encoder.Encode("some-other-event"); err != nil {
+		t.Fatalf("failed to encode fixture: %v", err)
+	}
+	body := encoder.Buffer()
+
+	h := &HessianCodec{
+		pkgType: PackageHeartbeat | PackageResponse,
+		reader:  bufio.NewReader(bytes.NewReader(body)),
+		bodyLen: len(body),
+		stream:  true,
+	}
+	event, err := h.ReadEvent()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Readonly {
+		t.Fatalf("got Readonly=true for event %q, want false", event.Event)
+	}
+}
+
+// heartbeatStubSerializer records which Write/WriteHeartbeat dispatched to —
+// MarshalRequest or MarshalResponse — and with what body, so WriteHeartbeat's
+// request/response dispatch and event-to-body mapping can be tested without depending on
+// Hessian2Serializer's packRequest/packResponse encode path.
+type heartbeatStubSerializer struct {
+	marshalRequestBody  any
+	marshalResponseBody any
+	sawRequest          bool
+	sawResponse         bool
+}
+
+func (s *heartbeatStubSerializer) MarshalRequest(service Service, header DubboHeader, body any) ([]byte, error) {
+	s.sawRequest = true
+	s.marshalRequestBody = body
+	return []byte("request"), nil
+}
+
+func (s *heartbeatStubSerializer) MarshalResponse(header DubboHeader, body any) ([]byte, error) {
+	s.sawResponse = true
+	s.marshalResponseBody = body
+	return []byte("response"), nil
+}
+
+func (s *heartbeatStubSerializer) UnmarshalRequest(data []byte, rspObj any) error {
+	return nil
+}
+
+func (s *heartbeatStubSerializer) UnmarshalResponse(header DubboHeader, data []byte, rspObj any) error {
+	return nil
+}
+
+func (s *heartbeatStubSerializer) UnmarshalRequestAttachments(data []byte) (map[string]any, error) {
+	return nil, nil
+}
+
+func (s *heartbeatStubSerializer) UnmarshalResponseAttachments(data []byte) (map[string]any, error) {
+	return nil, nil
+}
+
+// heartbeatTestSerialID is reserved for heartbeatStubSerializer, picked well clear of the
+// real ids in serializer.go so it can never collide with a registered one.
+const heartbeatTestSerialID byte = 200
+
+func TestHessianCodec_WriteHeartbeat_ZeroResponseStatusIsARequest(t *testing.T) {
+	s := &heartbeatStubSerializer{}
+	SetSerializer(heartbeatTestSerialID, s)
+
+	h := &HessianCodec{}
+	out, err := h.WriteHeartbeat(DubboHeader{SerialID: heartbeatTestSerialID, ResponseStatus: Zero}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !s.sawRequest || s.sawResponse {
+		t.Fatalf("ResponseStatus=Zero must dispatch to MarshalRequest, not MarshalResponse")
+	}
+	if string(out) != "request" {
+		t.Fatalf("got %q, want MarshalRequest's output", out)
+	}
+	if s.marshalRequestBody != nil {
+		t.Fatalf("a nil event must encode a nil body, got %v", s.marshalRequestBody)
+	}
+}
+
+func TestHessianCodec_WriteHeartbeat_NonZeroResponseStatusIsAResponse(t *testing.T) {
+	s := &heartbeatStubSerializer{}
+	SetSerializer(heartbeatTestSerialID, s)
+
+	h := &HessianCodec{}
+	if _, err := h.WriteHeartbeat(DubboHeader{SerialID: heartbeatTestSerialID, ResponseStatus: Response_OK}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !s.sawResponse || s.sawRequest {
+		t.Fatalf("a non-Zero ResponseStatus must dispatch to MarshalResponse, not MarshalRequest")
+	}
+	if s.marshalResponseBody != nil {
+		t.Fatalf("a nil event must encode a nil body, got %v", s.marshalResponseBody)
+	}
+}
+
+func TestHessianCodec_WriteHeartbeat_ReadonlyEventEncodesEventReadonlyString(t *testing.T) {
+	s := &heartbeatStubSerializer{}
+	SetSerializer(heartbeatTestSerialID, s)
+
+	h := &HessianCodec{}
+	if _, err := h.WriteHeartbeat(DubboHeader{SerialID: heartbeatTestSerialID, ResponseStatus: Response_OK}, &DubboEvent{Readonly: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.marshalResponseBody != EventReadonly {
+		t.Fatalf("got %v, want %q", s.marshalResponseBody, EventReadonly)
+	}
+}
+
+func TestHessianCodec_WriteHeartbeat_NonReadonlyEventPassesEventThrough(t *testing.T) {
+	s := &heartbeatStubSerializer{}
+	SetSerializer(heartbeatTestSerialID, s)
+
+	h := &HessianCodec{}
+	if _, err := h.WriteHeartbeat(DubboHeader{SerialID: heartbeatTestSerialID, ResponseStatus: Response_OK}, &DubboEvent{Event: "something-else"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.marshalResponseBody != "something-else" {
+		t.Fatalf("got %v, want the event's own value passed through unchanged", s.marshalResponseBody)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hessian2
+
+import (
+	"github.com/apache/dubbo-go-hessian2"
+
+	perrors "github.com/pkg/errors"
+)
+
+// Hessian2Serializer is the default Serializer, preserving the historical behavior of
+// HessianCodec from before serialization became pluggable.
+type Hessian2Serializer struct{}
+
+// MarshalRequest packs service metadata and the request body the same way packRequest
+// always has; it's the SerialID_Hessian2 entry point into Write.
+func (*Hessian2Serializer) MarshalRequest(service Service, header DubboHeader, body any) ([]byte, error) {
+	return packRequest(service, header, body)
+}
+
+// MarshalResponse packs the response the same way packResponse always has.
+func (*Hessian2Serializer) MarshalResponse(header DubboHeader, body any) ([]byte, error) {
+	return packResponse(header, body)
+}
+
+// UnmarshalRequest decodes a hessian2-encoded request body.
+func (*Hessian2Serializer) UnmarshalRequest(data []byte, rspObj any) error {
+	return perrors.WithStack(unpackRequestBody(hessian.NewDecoder(data), rspObj))
+}
+
+// UnmarshalResponse decodes a hessian2-encoded response body. When header carries
+// PackageResponse_Exception the body is a plain exception message rather than the
+// normal response shape, mirroring HessianCodec's historical ReadBody behavior.
+func (*Hessian2Serializer) UnmarshalResponse(header DubboHeader, data []byte, rspObj any) error {
+	if header.Type&PackageResponse_Exception != 0 {
+		decoder := hessian.NewDecoder(data)
+		exception, err := decoder.Decode()
+		if err != nil {
+			return perrors.WithStack(err)
+		}
+		rsp, ok := rspObj.(*DubboResponse)
+		if !ok {
+			return perrors.Errorf("java exception:%s", exception.(string))
+		}
+		rsp.Exception = perrors.Errorf("java exception:%s", exception.(string))
+		return nil
+	}
+	return perrors.WithStack(unpackResponseBody(hessian.NewDecoder(data), rspObj))
+}
+
+// UnmarshalRequestAttachments decodes only the attachments, skipping the arguments.
+func (*Hessian2Serializer) UnmarshalRequestAttachments(data []byte) (map[string]any, error) {
+	rspObj := make([]any, 7)
+	if err := unpackRequestBody(hessian.NewDecoderWithSkip(data), rspObj); err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	return rspObj[6].(map[string]any), nil
+}
+
+// UnmarshalResponseAttachments decodes only the attachments, skipping the result value.
+func (*Hessian2Serializer) UnmarshalResponseAttachments(data []byte) (map[string]any, error) {
+	rspObj := &DubboResponse{}
+	if err := unpackResponseBody(hessian.NewDecoderWithSkip(data), rspObj); err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	return rspObj.Attachments, nil
+}
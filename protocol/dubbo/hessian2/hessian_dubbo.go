@@ -52,6 +52,29 @@ type DubboHeader struct {
 	ResponseStatus byte
 }
 
+// IsHeartbeat reports whether Type carries the FLAG_EVENT bit. A heartbeat packet is
+// normally also flagged PackageRequest (and PackageRequest_TwoWay) or PackageResponse, so
+// callers that need to special-case heartbeats must check this bit on its own rather than
+// comparing Type against an exact combined value.
+func (h DubboHeader) IsHeartbeat() bool {
+	return h.Type&PackageHeartbeat != 0
+}
+
+// IsRequest reports whether Type carries the request bit, heartbeat or not.
+func (h DubboHeader) IsRequest() bool {
+	return h.Type&PackageRequest != 0
+}
+
+// IsResponse reports whether Type carries the response bit, heartbeat or not.
+func (h DubboHeader) IsResponse() bool {
+	return h.Type&PackageResponse != 0
+}
+
+// IsException reports whether Type carries the PackageResponse_Exception bit.
+func (h DubboHeader) IsException() bool {
+	return h.Type&PackageResponse_Exception != 0
+}
+
 // Service defines service instance
 type Service struct {
 	Path      string
@@ -64,10 +87,11 @@ type Service struct {
 
 // HessianCodec defines hessian codec
 type HessianCodec struct {
-	pkgType PackageType
-	reader  *bufio.Reader
-	bodyLen int
-	stream  bool
+	pkgType  PackageType
+	reader   *bufio.Reader
+	bodyLen  int
+	stream   bool
+	serialID byte
 }
 
 // NewHessianCodec generate a new hessian codec instance
@@ -86,7 +110,12 @@ func NewHessianCodecCustom(pkgType PackageType, reader *bufio.Reader, bodyLen in
 	}
 }
 
-// NewStreamHessianCodecCustom generate a new hessian codec instance
+// NewStreamHessianCodecCustom generates a new hessian codec instance that opts into the
+// pooled body-read path: ReadBody and ReadAttachments bound their read with an
+// io.LimitedReader over bodyLen and, for bodies at or under smallBodyThreshold, read into a
+// reused scratch buffer instead of allocating a fresh bodyLen-sized one up front. This is a
+// buffer-reuse optimization, not incremental decoding — the full body still lands in one
+// contiguous slice before the serializer sees any of it, for bodies of every size.
 func NewStreamHessianCodecCustom(pkgType PackageType, reader *bufio.Reader, bodyLen int) *HessianCodec {
 	return &HessianCodec{
 		pkgType: pkgType,
@@ -97,18 +126,30 @@ func NewStreamHessianCodecCustom(pkgType PackageType, reader *bufio.Reader, body
 }
 
 func (h *HessianCodec) Write(service Service, header DubboHeader, body any) ([]byte, error) {
+	// A caller that builds a DubboHeader without setting SerialID (every call site
+	// predating SerializationKey) gets Hessian2, same as SerialIDFromName's own default
+	// for an empty/unrecognized name, so this can't break anyone who never populated it.
+	if header.SerialID == Zero {
+		header.SerialID = SerialIDFromName("")
+	}
+
+	serializer, err := GetSerializer(header.SerialID)
+	if err != nil {
+		return nil, err
+	}
+
 	switch header.Type {
 	case PackageHeartbeat:
 		if header.ResponseStatus == Zero {
-			return packRequest(service, header, body)
+			return serializer.MarshalRequest(service, header, body)
 		}
-		return packResponse(header, body)
+		return serializer.MarshalResponse(header, body)
 
 	case PackageRequest, PackageRequest_TwoWay:
-		return packRequest(service, header, body)
+		return serializer.MarshalRequest(service, header, body)
 
 	case PackageResponse:
-		return packResponse(header, body)
+		return serializer.MarshalResponse(header, body)
 
 	default:
 		return nil, perrors.Errorf("Unrecognized message type: %v", header.Type)
@@ -162,6 +203,9 @@ func (h *HessianCodec) ReadHeader(header *DubboHeader) error {
 		return perrors.Errorf("serialization ID:%v", header.SerialID)
 	}
 
+	// A packet can carry more than one of these bits at once (a heartbeat request is also
+	// flagged PackageRequest, and two-way), so Type is built as a bitmask, not an enum;
+	// dispatch on it with DubboHeader's IsXxx helpers, never with an exact equality check.
 	flag := buf[2] & FLAG_EVENT
 	if flag != Zero {
 		header.Type |= PackageHeartbeat
@@ -192,6 +236,7 @@ func (h *HessianCodec) ReadHeader(header *DubboHeader) error {
 
 	h.pkgType = header.Type
 	h.bodyLen = header.BodyLen
+	h.serialID = header.SerialID
 
 	if h.reader.Buffered() < h.bodyLen && !h.stream {
 		return ErrBodyNotEnough
@@ -202,58 +247,45 @@ func (h *HessianCodec) ReadHeader(header *DubboHeader) error {
 
 // ReadBody uses hessian codec to read response body
 func (h *HessianCodec) ReadBody(rspObj any) error {
-	var (
-		err error
-		buf []byte
-	)
-
-	if h.stream {
-		buf = make([]byte, h.bodyLen)
-		readLen, n := 0, 0
-		for readLen < h.bodyLen {
-			n, err = h.reader.Read(buf[readLen:])
-			if err != nil {
-				return perrors.WithStack(err)
-			}
-			readLen += n
-		}
-	} else {
-		if h.reader.Buffered() < h.bodyLen {
-			return ErrBodyNotEnough
-		}
-		buf, err = h.reader.Peek(h.bodyLen)
-		if err != nil {
-			return perrors.WithStack(err)
-		}
-		_, err = h.reader.Discard(h.bodyLen)
-		if err != nil { // this is impossible
-			return perrors.WithStack(err)
-		}
+	buf, release, err := h.readRawBody()
+	if err != nil {
+		return perrors.WithStack(err)
 	}
-
-	switch h.pkgType & PackageType_BitSize {
-	case PackageResponse | PackageHeartbeat | PackageResponse_Exception, PackageResponse | PackageResponse_Exception:
-		decoder := hessian.NewDecoder(buf[:])
-		exception, exceptionErr := decoder.Decode()
-		if exceptionErr != nil {
-			return perrors.WithStack(exceptionErr)
-		}
-		rsp, ok := rspObj.(*DubboResponse)
-		if !ok {
-			return perrors.Errorf("java exception:%s", exception.(string))
+	defer release()
+
+	// Dispatch on the individual flag bits via DubboHeader's IsXxx helpers rather than
+	// exact combined Type values: a heartbeat request is normally also flagged
+	// PackageRequest_TwoWay (the sender expects a heartbeat response back), and matching
+	// only the bare PackageRequest|PackageHeartbeat combination would silently miss it.
+	// Checking IsHeartbeat first, independent of every other bit, makes the dispatch
+	// correct for any combination callers throw at it.
+	hdr := DubboHeader{Type: h.pkgType}
+	switch {
+	case hdr.IsHeartbeat():
+		// Heartbeat/event bodies have their own shape; read them with ReadEvent instead.
+	case hdr.IsException():
+		serializer, serializerErr := GetSerializer(h.serialID)
+		if serializerErr != nil {
+			return serializerErr
 		}
-		rsp.Exception = perrors.Errorf("java exception:%s", exception.(string))
-		return nil
-	case PackageRequest | PackageHeartbeat, PackageResponse | PackageHeartbeat:
-	case PackageRequest:
+		return serializer.UnmarshalResponse(hdr, buf[:], rspObj)
+	case hdr.IsRequest():
 		if rspObj != nil {
-			if err = unpackRequestBody(hessian.NewDecoder(buf[:]), rspObj); err != nil {
+			serializer, serializerErr := GetSerializer(h.serialID)
+			if serializerErr != nil {
+				return serializerErr
+			}
+			if err = serializer.UnmarshalRequest(buf[:], rspObj); err != nil {
 				return perrors.WithStack(err)
 			}
 		}
-	case PackageResponse:
+	case hdr.IsResponse():
 		if rspObj != nil {
-			if err = unpackResponseBody(hessian.NewDecoder(buf[:]), rspObj); err != nil {
+			serializer, serializerErr := GetSerializer(h.serialID)
+			if serializerErr != nil {
+				return serializerErr
+			}
+			if err = serializer.UnmarshalResponse(hdr, buf[:], rspObj); err != nil {
 				return perrors.WithStack(err)
 			}
 		}
@@ -262,50 +294,126 @@ func (h *HessianCodec) ReadBody(rspObj any) error {
 	return nil
 }
 
-// ignore body, but only read attachments
-func (h *HessianCodec) ReadAttachments() (map[string]any, error) {
-	var (
-		err error
-		buf []byte
-	)
+// DubboEvent is the decoded body of a heartbeat (FLAG_EVENT) packet. Dubbo sends a null
+// event body for a plain heartbeat; newer versions may instead carry the READONLY event
+// string, telling the peer this connection is going away and shouldn't be routed new
+// two-way requests.
+type DubboEvent struct {
+	// Event is the raw decoded event value: nil for a plain heartbeat, or a string such
+	// as EventReadonly.
+	Event any
+	// Readonly is true when Event is the READONLY event.
+	Readonly bool
+}
 
-	if h.stream {
-		buf = make([]byte, h.bodyLen)
-		readLen, n := 0, 0
-		for readLen < h.bodyLen {
-			n, err = h.reader.Read(buf[readLen:])
-			if err != nil {
-				return nil, perrors.WithStack(err)
-			}
-			readLen += n
-		}
-	} else {
-		if h.reader.Buffered() < h.bodyLen {
-			return nil, ErrBodyNotEnough
+// EventReadonly is the event payload a Dubbo provider sends to tell a consumer this
+// connection has gone read-only: stop routing new two-way requests to it.
+const EventReadonly = "R"
+
+// ReadEvent decodes a heartbeat body read by ReadHeader into a DubboEvent. It must only
+// be called when the just-read header has the PackageHeartbeat bit set.
+func (h *HessianCodec) ReadEvent() (*DubboEvent, error) {
+	if !(DubboHeader{Type: h.pkgType}).IsHeartbeat() {
+		return nil, perrors.Errorf("hessian2: ReadEvent called for a non-heartbeat packet (type %v)", h.pkgType)
+	}
+
+	if h.bodyLen == 0 {
+		return &DubboEvent{}, nil
+	}
+
+	buf, release, err := h.readRawBody()
+	if err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	defer release()
+
+	val, err := hessian.NewDecoder(buf).Decode()
+	if err != nil {
+		return nil, perrors.WithStack(err)
+	}
+
+	event := &DubboEvent{Event: val}
+	if s, ok := val.(string); ok && s == EventReadonly {
+		event.Readonly = true
+	}
+	return event, nil
+}
+
+// WriteHeartbeat builds the bytes for a heartbeat packet, hessian-encoding event's value
+// (nil for a plain heartbeat, or EventReadonly to propagate READONLY) the same way a
+// normal request/response body is encoded, so it still goes through the SerialID-selected
+// Serializer. header.ResponseStatus distinguishes a heartbeat request (Zero) from a
+// heartbeat response, exactly as Write does.
+func (h *HessianCodec) WriteHeartbeat(header DubboHeader, event *DubboEvent) ([]byte, error) {
+	header.Type = PackageHeartbeat
+
+	var body any
+	if event != nil {
+		body = event.Event
+		if event.Readonly {
+			body = EventReadonly
 		}
-		buf, err = h.reader.Peek(h.bodyLen)
+	}
+
+	return h.Write(Service{}, header, body)
+}
+
+// readRawBody reads exactly h.bodyLen bytes off h.reader, using the pooled or
+// peek-and-discard path depending on how the codec was constructed; either way the result
+// is the full body in one contiguous slice, not an incremental stream. The caller must
+// invoke the returned release func once done with the slice.
+func (h *HessianCodec) readRawBody() ([]byte, func(), error) {
+	if h.stream {
+		buf, release, err := readStreamBody(h.reader, h.bodyLen)
 		if err != nil {
-			return nil, perrors.WithStack(err)
-		}
-		_, err = h.reader.Discard(h.bodyLen)
-		if err != nil { // this is impossible
-			return nil, perrors.WithStack(err)
+			return nil, nil, perrors.WithStack(err)
 		}
+		return buf, release, nil
 	}
 
-	switch h.pkgType & PackageType_BitSize {
-	case PackageRequest:
-		rspObj := make([]any, 7)
-		if err = unpackRequestBody(hessian.NewDecoderWithSkip(buf[:]), rspObj); err != nil {
-			return nil, perrors.WithStack(err)
+	if h.reader.Buffered() < h.bodyLen {
+		return nil, nil, ErrBodyNotEnough
+	}
+	buf, err := h.reader.Peek(h.bodyLen)
+	if err != nil {
+		return nil, nil, perrors.WithStack(err)
+	}
+	if _, err = h.reader.Discard(h.bodyLen); err != nil { // this is impossible
+		return nil, nil, perrors.WithStack(err)
+	}
+	return buf, func() {}, nil
+}
+
+// ignore body, but only read attachments
+func (h *HessianCodec) ReadAttachments() (map[string]any, error) {
+	buf, release, err := h.readRawBody()
+	if err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	defer release()
+
+	// See the comment on ReadBody's switch: check IsHeartbeat and IsException on their own
+	// first, in that order, since a heartbeat or exception packet can otherwise also carry
+	// the request/response bit. An exception response's body is a plain hessian2-encoded
+	// string (see ReadBody), not the attachments envelope, so there's nothing to decode.
+	hdr := DubboHeader{Type: h.pkgType}
+	switch {
+	case hdr.IsHeartbeat():
+		return nil, nil
+	case hdr.IsException():
+		return nil, nil
+	case hdr.IsRequest():
+		serializer, serializerErr := GetSerializer(h.serialID)
+		if serializerErr != nil {
+			return nil, serializerErr
 		}
-		return rspObj[6].(map[string]any), nil
-	case PackageResponse:
-		rspObj := &DubboResponse{}
-		if err = unpackResponseBody(hessian.NewDecoderWithSkip(buf[:]), rspObj); err != nil {
-			return nil, perrors.WithStack(err)
+		return serializer.UnmarshalRequestAttachments(buf[:])
+	case hdr.IsResponse():
+		serializer, serializerErr := GetSerializer(h.serialID)
+		if serializerErr != nil {
+			return nil, serializerErr
 		}
-		return rspObj.Attachments, nil
+		return serializer.UnmarshalResponseAttachments(buf[:])
 	}
 
 	return nil, nil
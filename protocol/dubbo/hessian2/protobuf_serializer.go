@@ -0,0 +1,244 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hessian2
+
+import (
+	"encoding/binary"
+)
+
+import (
+	"github.com/apache/dubbo-go-hessian2"
+	"google.golang.org/protobuf/proto"
+
+	perrors "github.com/pkg/errors"
+)
+
+// envelopeLenSize is the width, in bytes, of the big-endian length prefix MarshalRequest
+// writes ahead of the hessian2-encoded envelope, so UnmarshalRequest knows exactly where
+// the envelope ends and the raw protobuf message bytes begin without needing the hessian2
+// decoder to report how much of the buffer it consumed.
+const envelopeLenSize = 4
+
+func init() {
+	s := &ProtobufSerializer{}
+	SetSerializer(SerialID_Protobuf, s)
+	SetSerializer(SerialID_ProtobufJSON, s)
+}
+
+// ProtobufRequest is the body shape ProtobufSerializer expects from HessianCodec.Write and
+// hands back from UnmarshalRequest: the single proto.Message argument plus attachments, in
+// place of the hessian2 arg-array convention. Path/Version/Method round-trip the Service
+// metadata MarshalRequest encoded into the envelope, so a provider decoding the request
+// can still learn which service/method was invoked.
+type ProtobufRequest struct {
+	Path        string
+	Version     string
+	Method      string
+	Message     proto.Message
+	Attachments map[string]any
+}
+
+// ProtobufResponse is the body shape ProtobufSerializer expects from HessianCodec.Write
+// and hands back from UnmarshalResponse.
+type ProtobufResponse struct {
+	Message proto.Message
+}
+
+// ProtobufSerializer is a Serializer for SerialID_Protobuf (and the legacy
+// SerialID_ProtobufJSON alias). Service metadata and attachments are still hessian2-encoded
+// so the envelope stays readable by peers that only inspect the header/attachments, while
+// the argument/result payload is encoded with the caller-supplied proto.Message.
+type ProtobufSerializer struct{}
+
+// MarshalRequest hessian2-encodes the Service metadata and attachments, then appends the
+// protobuf-encoded message.
+func (*ProtobufSerializer) MarshalRequest(service Service, header DubboHeader, body any) ([]byte, error) {
+	req, ok := body.(*ProtobufRequest)
+	if !ok {
+		return nil, perrors.Errorf("hessian2: ProtobufSerializer.MarshalRequest expects *ProtobufRequest, got %T", body)
+	}
+
+	encoder := hessian.NewEncoder()
+	if err := encoder.Encode(service.Path); err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	if err := encoder.Encode(service.Version); err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	if err := encoder.Encode(service.Method); err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	if err := encoder.Encode(req.Attachments); err != nil {
+		return nil, perrors.WithStack(err)
+	}
+
+	msgBytes, err := proto.Marshal(req.Message)
+	if err != nil {
+		return nil, perrors.WithStack(err)
+	}
+
+	envelope := encoder.Buffer()
+	out := make([]byte, envelopeLenSize, envelopeLenSize+len(envelope)+len(msgBytes))
+	binary.BigEndian.PutUint32(out, uint32(len(envelope)))
+	out = append(out, envelope...)
+	out = append(out, msgBytes...)
+	return out, nil
+}
+
+// MarshalResponse protobuf-encodes the response message; there's no metadata envelope to
+// carry on the response side, unlike MarshalRequest.
+func (*ProtobufSerializer) MarshalResponse(header DubboHeader, body any) ([]byte, error) {
+	resp, ok := body.(*ProtobufResponse)
+	if !ok {
+		return nil, perrors.Errorf("hessian2: ProtobufSerializer.MarshalResponse expects *ProtobufResponse, got %T", body)
+	}
+	msgBytes, err := proto.Marshal(resp.Message)
+	if err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	return msgBytes, nil
+}
+
+// splitEnvelope separates data, as written by MarshalRequest, into the hessian2-encoded
+// envelope (path/version/method/attachments) and the raw protobuf message bytes that
+// follow it, using the length prefix MarshalRequest wrote ahead of the envelope.
+func splitEnvelope(data []byte) (envelope, msgBytes []byte, err error) {
+	if len(data) < envelopeLenSize {
+		return nil, nil, perrors.Errorf("hessian2: protobuf payload too short to hold an envelope length prefix: %d bytes", len(data))
+	}
+	envLen := int(binary.BigEndian.Uint32(data))
+	data = data[envelopeLenSize:]
+	if envLen > len(data) {
+		return nil, nil, perrors.Errorf("hessian2: protobuf envelope length %d exceeds remaining payload of %d bytes", envLen, len(data))
+	}
+	return data[:envLen], data[envLen:], nil
+}
+
+// UnmarshalRequest decodes the hessian2-encoded envelope written by MarshalRequest and
+// proto.Unmarshals the remaining bytes into rspObj.(*ProtobufRequest).Message, which the
+// caller must pre-populate with a concrete proto.Message so this can decode into the right
+// type.
+func (*ProtobufSerializer) UnmarshalRequest(data []byte, rspObj any) error {
+	req, ok := rspObj.(*ProtobufRequest)
+	if !ok {
+		return perrors.Errorf("hessian2: ProtobufSerializer.UnmarshalRequest expects *ProtobufRequest, got %T", rspObj)
+	}
+
+	envelope, msgBytes, err := splitEnvelope(data)
+	if err != nil {
+		return err
+	}
+
+	decoder := hessian.NewDecoder(envelope)
+	path, err := decoder.Decode()
+	if err != nil {
+		return perrors.WithStack(err)
+	}
+	version, err := decoder.Decode()
+	if err != nil {
+		return perrors.WithStack(err)
+	}
+	method, err := decoder.Decode()
+	if err != nil {
+		return perrors.WithStack(err)
+	}
+	attachments, err := decoder.Decode()
+	if err != nil {
+		return perrors.WithStack(err)
+	}
+	if s, ok := path.(string); ok {
+		req.Path = s
+	}
+	if s, ok := version.(string); ok {
+		req.Version = s
+	}
+	if s, ok := method.(string); ok {
+		req.Method = s
+	}
+	if m, ok := attachments.(map[string]any); ok {
+		req.Attachments = m
+	}
+
+	if req.Message == nil {
+		return perrors.New("hessian2: ProtobufRequest.Message must be pre-populated with a concrete proto.Message before UnmarshalRequest")
+	}
+	return perrors.WithStack(proto.Unmarshal(msgBytes, req.Message))
+}
+
+// UnmarshalResponse decodes a protobuf response body. When header carries
+// PackageResponse_Exception, the body is still a plain hessian2-encoded exception
+// message, matching Hessian2Serializer and every other wire format.
+func (*ProtobufSerializer) UnmarshalResponse(header DubboHeader, data []byte, rspObj any) error {
+	if header.Type&PackageResponse_Exception != 0 {
+		decoder := hessian.NewDecoder(data)
+		exception, err := decoder.Decode()
+		if err != nil {
+			return perrors.WithStack(err)
+		}
+		rsp, ok := rspObj.(*DubboResponse)
+		if !ok {
+			return perrors.Errorf("java exception:%s", exception.(string))
+		}
+		rsp.Exception = perrors.Errorf("java exception:%s", exception.(string))
+		return nil
+	}
+
+	resp, ok := rspObj.(*ProtobufResponse)
+	if !ok {
+		return perrors.Errorf("hessian2: ProtobufSerializer.UnmarshalResponse expects *ProtobufResponse, got %T", rspObj)
+	}
+	if resp.Message == nil {
+		return perrors.New("hessian2: ProtobufResponse.Message must be pre-populated with a concrete proto.Message before UnmarshalResponse")
+	}
+	return perrors.WithStack(proto.Unmarshal(data, resp.Message))
+}
+
+// UnmarshalRequestAttachments decodes only the hessian2-encoded envelope fields that
+// precede the protobuf message, returning the attachments without touching the message
+// bytes.
+func (*ProtobufSerializer) UnmarshalRequestAttachments(data []byte) (map[string]any, error) {
+	envelope, _, err := splitEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := hessian.NewDecoder(envelope)
+	if _, err := decoder.Decode(); err != nil { // path
+		return nil, perrors.WithStack(err)
+	}
+	if _, err := decoder.Decode(); err != nil { // version
+		return nil, perrors.WithStack(err)
+	}
+	if _, err := decoder.Decode(); err != nil { // method
+		return nil, perrors.WithStack(err)
+	}
+	attachments, err := decoder.Decode()
+	if err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	m, ok := attachments.(map[string]any)
+	if !ok {
+		return nil, perrors.Errorf("hessian2: protobuf request attachments have unexpected type %T", attachments)
+	}
+	return m, nil
+}
+
+// UnmarshalResponseAttachments is a no-op: ProtobufResponse carries no attachment
+// envelope, so there's nothing to decode.
+func (*ProtobufSerializer) UnmarshalResponseAttachments(data []byte) (map[string]any, error) {
+	return nil, nil
+}
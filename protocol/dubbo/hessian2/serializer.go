@@ -0,0 +1,110 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hessian2
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+// SerialID enumerates the wire serialization ids carried in the dubbo header (buf[2] &
+// SERIAL_MASK). These mirror the ids defined by the Dubbo2 protocol spec.
+const (
+	SerialID_Hessian2     byte = 2
+	SerialID_FastJSON     byte = 6
+	SerialID_Kryo         byte = 8
+	SerialID_Protobuf     byte = 21
+	SerialID_ProtobufJSON byte = 22
+)
+
+// SerializationKey is the URL parameter used to pick the wire serialization for a
+// service, e.g. "dubbo://host:port/Service?serialization=protobuf".
+const SerializationKey = "serialization"
+
+// name-to-SerialID aliases accepted in the SerializationKey URL parameter.
+const (
+	Hessian2Serialization = "hessian2"
+	FastJSONSerialization = "fastjson"
+	KryoSerialization     = "kryo"
+	ProtobufSerialization = "protobuf"
+)
+
+var serializationNameToID = map[string]byte{
+	Hessian2Serialization: SerialID_Hessian2,
+	FastJSONSerialization: SerialID_FastJSON,
+	KryoSerialization:     SerialID_Kryo,
+	ProtobufSerialization: SerialID_Protobuf,
+}
+
+// SerialIDFromName resolves the SerializationKey URL value to its wire SerialID,
+// defaulting to Hessian2 for an empty or unrecognized name so existing URLs keep working.
+func SerialIDFromName(name string) byte {
+	if id, ok := serializationNameToID[name]; ok {
+		return id
+	}
+	return SerialID_Hessian2
+}
+
+// Serializer packs and unpacks dubbo request/response bodies for one wire serialization.
+// Implementations are registered against a SerialID with SetSerializer and looked up by
+// HessianCodec at Write/ReadBody/ReadAttachments time based on DubboHeader.SerialID, so
+// callers never need to know which wire format is in play.
+type Serializer interface {
+	// MarshalRequest packs service metadata and the request body into wire bytes.
+	MarshalRequest(service Service, header DubboHeader, body any) ([]byte, error)
+
+	// MarshalResponse packs a response header and body into wire bytes.
+	MarshalResponse(header DubboHeader, body any) ([]byte, error)
+
+	// UnmarshalRequest decodes a request body produced by MarshalRequest into rspObj.
+	UnmarshalRequest(data []byte, rspObj any) error
+
+	// UnmarshalResponse decodes a response body produced by MarshalResponse into rspObj.
+	// header is passed through so an implementation can special-case the
+	// PackageResponse_Exception body shape, which is always a plain error message.
+	UnmarshalResponse(header DubboHeader, data []byte, rspObj any) error
+
+	// UnmarshalRequestAttachments decodes only the attachments of a request body, skipping
+	// the arguments, so ReadAttachments doesn't pay for decoding data it discards.
+	UnmarshalRequestAttachments(data []byte) (map[string]any, error)
+
+	// UnmarshalResponseAttachments decodes only the attachments of a response body,
+	// skipping the result value.
+	UnmarshalResponseAttachments(data []byte) (map[string]any, error)
+}
+
+var serializerRegistry = make(map[byte]Serializer, 8)
+
+// SetSerializer registers a Serializer implementation for a SerialID. Implementations
+// typically call this from their own init(), mirroring the common/extension registration
+// style used elsewhere in dubbo-go.
+func SetSerializer(id byte, s Serializer) {
+	serializerRegistry[id] = s
+}
+
+// GetSerializer returns the Serializer registered for id.
+func GetSerializer(id byte) (Serializer, error) {
+	s, ok := serializerRegistry[id]
+	if !ok {
+		return nil, perrors.Errorf("hessian2: no Serializer registered for SerialID %v", id)
+	}
+	return s, nil
+}
+
+func init() {
+	SetSerializer(SerialID_Hessian2, &Hessian2Serializer{})
+}
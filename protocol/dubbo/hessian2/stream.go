@@ -0,0 +1,69 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hessian2
+
+import (
+	"io"
+	"sync"
+)
+
+// smallBodyThreshold is the body size, in bytes, at or under which readStreamBody reuses a
+// pooled scratch buffer instead of allocating a fresh one. Bodies above it read
+// straight into a freshly allocated slice, since pooling large and widely varying sizes
+// buys little and risks pinning oversized buffers in the pool. Either way the body is read
+// to completion in one contiguous slice before the caller decodes it — this trades off
+// allocations for small, frequent bodies, it does not turn decoding into a streaming,
+// partial-buffer operation for large ones.
+const smallBodyThreshold = 64 * 1024
+
+var scratchBufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, smallBodyThreshold)
+		return &b
+	},
+}
+
+// readStreamBody reads exactly n bytes of a dubbo body out of r, which NewStreamHessianCodecCustom
+// callers expect to be the connection's bufio.Reader, and returns them as a single
+// contiguous slice — this is a pooled-allocation optimization, not an incremental decoder,
+// so large bodies still fully materialize in memory before the caller can look at any of
+// them. It bounds the read with an io.LimitedReader so a serializer bug can't run past the
+// frame into the next packet, and uses io.ReadFull so short reads across TCP segment
+// boundaries are transparently retried rather than surfacing as a partial body. For n at or
+// under smallBodyThreshold it borrows a buffer from scratchBufferPool instead of allocating,
+// which is the common case for everyday RPC args; the caller must invoke the returned
+// release func once it's done with the slice, typically right after decoding out of it.
+func readStreamBody(r io.Reader, n int) (buf []byte, release func(), err error) {
+	limited := &io.LimitedReader{R: r, N: int64(n)}
+
+	if n <= smallBodyThreshold {
+		bp := scratchBufferPool.Get().(*[]byte)
+		buf = (*bp)[:n]
+		if _, err = io.ReadFull(limited, buf); err != nil {
+			scratchBufferPool.Put(bp)
+			return nil, nil, err
+		}
+		return buf, func() { scratchBufferPool.Put(bp) }, nil
+	}
+
+	buf = make([]byte, n)
+	if _, err = io.ReadFull(limited, buf); err != nil {
+		return nil, nil, err
+	}
+	return buf, func() {}, nil
+}
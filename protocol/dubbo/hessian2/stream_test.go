@@ -0,0 +1,192 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hessian2
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+// shortReader dribbles out data a few bytes at a time, simulating a body split across
+// several TCP segments.
+type shortReader struct {
+	data     []byte
+	chunk    int
+	errAt    int // return errAfter once this many bytes have been served, -1 to disable
+	errAfter error
+}
+
+func (r *shortReader) Read(p []byte) (int, error) {
+	if r.errAt == 0 && r.errAfter != nil {
+		return 0, r.errAfter
+	}
+	n := r.chunk
+	if n > len(p) {
+		n = len(p)
+	}
+	if n > len(r.data) {
+		n = len(r.data)
+	}
+	copy(p, r.data[:n])
+	r.data = r.data[n:]
+	if r.errAt > 0 {
+		r.errAt -= n
+	}
+	if len(r.data) == 0 && r.errAfter == nil {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func TestReadStreamBody_SmallBodyUsesPool(t *testing.T) {
+	payload := bytes.Repeat([]byte("a"), 128)
+	buf, release, err := readStreamBody(bytes.NewReader(payload), len(payload))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer release()
+
+	if !bytes.Equal(buf, payload) {
+		t.Fatalf("got %q, want %q", buf, payload)
+	}
+}
+
+func TestReadStreamBody_LargeBodySkipsPool(t *testing.T) {
+	payload := bytes.Repeat([]byte("b"), smallBodyThreshold+1)
+	buf, release, err := readStreamBody(bytes.NewReader(payload), len(payload))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer release()
+
+	if !bytes.Equal(buf, payload) {
+		t.Fatalf("large body mismatch, got %d bytes want %d", len(buf), len(payload))
+	}
+}
+
+func TestReadStreamBody_ShortReadsAcrossBoundaries(t *testing.T) {
+	payload := bytes.Repeat([]byte("c"), 4096)
+	r := &shortReader{data: append([]byte(nil), payload...), chunk: 17}
+
+	buf, release, err := readStreamBody(r, len(payload))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer release()
+
+	if !bytes.Equal(buf, payload) {
+		t.Fatalf("short-read reassembly mismatch")
+	}
+}
+
+func TestReadStreamBody_ErrorMidStream(t *testing.T) {
+	boom := errors.New("connection reset")
+	r := &shortReader{data: bytes.Repeat([]byte("d"), 256), chunk: 32, errAt: 64, errAfter: boom}
+
+	_, _, err := readStreamBody(r, 256)
+	if err == nil {
+		t.Fatal("expected an error when the underlying reader fails mid-body")
+	}
+}
+
+func TestReadStreamBody_BoundedByLimitedReader(t *testing.T) {
+	payload := append(bytes.Repeat([]byte("e"), 10), bytes.Repeat([]byte("f"), 10)...)
+	buf, release, err := readStreamBody(bytes.NewReader(payload), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer release()
+
+	want := bytes.Repeat([]byte("e"), 10)
+	if !bytes.Equal(buf, want) {
+		t.Fatalf("got %q, want %q; readStreamBody must not read past bodyLen", buf, want)
+	}
+}
+
+// TestReadStreamBody_PoolReuseDoesNotCorruptConcurrentReaders pins down the safety
+// contract readStreamBody's callers rely on: a goroutine that copies buf out (what every
+// real Serializer.Unmarshal* does, e.g. hessian.NewDecoder(data) or proto.Unmarshal, both
+// of which copy rather than alias their input) before calling release is never handed data
+// that belongs to a different, concurrently in-flight call, even though every call under
+// smallBodyThreshold is drawing from the same shared scratchBufferPool. It does not prove
+// anything about whether the real hessian2/protobuf decoders copy before returning, only
+// that the pool/release mechanics themselves hand out exclusive buffers.
+func TestReadStreamBody_PoolReuseDoesNotCorruptConcurrentReaders(t *testing.T) {
+	const goroutines = 32
+	const itersPerGoroutine = 200
+	const bodyLen = 4096 // well under smallBodyThreshold, so every call hits the pool
+
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines)
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			want := bytes.Repeat([]byte{byte(g)}, bodyLen)
+			for i := 0; i < itersPerGoroutine; i++ {
+				buf, release, err := readStreamBody(bytes.NewReader(want), bodyLen)
+				if err != nil {
+					errs <- err
+					return
+				}
+				got := append([]byte(nil), buf...) // copy before release, as a real decoder would
+				release()
+				if !bytes.Equal(got, want) {
+					errs <- errors.New("pooled buffer corrupted by a concurrent reader")
+					return
+				}
+			}
+		}(g)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkReadStreamBody_Small(b *testing.B) {
+	payload := bytes.Repeat([]byte("x"), 512)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf, release, err := readStreamBody(bytes.NewReader(payload), len(payload))
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = buf
+		release()
+	}
+}
+
+func BenchmarkReadStreamBody_Large(b *testing.B) {
+	payload := bytes.Repeat([]byte("x"), smallBodyThreshold*4)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf, release, err := readStreamBody(bytes.NewReader(payload), len(payload))
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = buf
+		release()
+	}
+}